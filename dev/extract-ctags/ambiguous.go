@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// heuristic is a single weighted regex signal used to disambiguate a file
+// extension that is claimed by more than one language, e.g. "@interface"
+// favouring Objective-C over C for a ".h" file.
+type heuristic struct {
+	Language string `yaml:"language"`
+	Pattern  string `yaml:"pattern"`
+	Weight   int    `yaml:"weight"`
+}
+
+// heuristicsFile is the shape of the hand-curated -heuristics YAML file, keyed
+// by bare file extension.
+type heuristicsFile map[string][]heuristic
+
+// loadHeuristics reads and parses the hand-curated heuristics YAML file. It
+// returns an empty map without error if path is unset, since heuristics are
+// optional: an extension with no curated signals simply can't be
+// disambiguated by content and falls back to detectLanguage's priority order
+// for its candidates, or failing that to its first candidate.
+func loadHeuristics(path string) (heuristicsFile, error) {
+	if path == "" {
+		return heuristicsFile{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var hf heuristicsFile
+	if err := yaml.Unmarshal(data, &hf); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for ext, heuristics := range hf {
+		for _, h := range heuristics {
+			if err := validateJSPattern(h.Pattern); err != nil {
+				return nil, fmt.Errorf("%s: extension %q, language %q: %w", path, ext, h.Language, err)
+			}
+		}
+	}
+	return hf, nil
+}
+
+// inlineFlagGroupRe matches a regex inline mode-modifier group such as
+// (?i), (?s), or (?im). These are valid in Go's regexp/RE2 syntax (which
+// yaml.Unmarshal and this tool otherwise don't care about) but not in a
+// JavaScript RegExp literal, so a pattern using one would compile fine here
+// and then fail to parse at all once emitted into ambiguousExtensions.ts.
+// Non-capturing groups (?:...), named groups (?<name>...), and lookarounds
+// (?=...) (?!...) (?<=...) (?<!...) are unaffected: none of them are made of
+// only letters between "(?" and ")".
+var inlineFlagGroupRe = regexp.MustCompile(`\(\?[a-zA-Z]+\)`)
+
+// validateJSPattern rejects a curated regex pattern that would fail to parse
+// as a JavaScript RegExp literal once emitted, so a bad pattern can't reach
+// the generated .ts file (and break every consumer that imports it) again.
+func validateJSPattern(pattern string) error {
+	if inlineFlagGroupRe.MatchString(pattern) {
+		return fmt.Errorf("pattern %q uses an inline mode flag group, which JavaScript RegExp literals don't support; rewrite the pattern instead (e.g. spell out both cases)", pattern)
+	}
+	return nil
+}
+
+// ambiguousExtensions returns, for each file extension claimed by more than
+// one language, the sorted list of candidate language IDs. Extensions
+// owned by exactly one language are omitted: they aren't ambiguous.
+func ambiguousExtensions(langs []langInfo) map[string][]string {
+	owners := make(map[string][]string)
+	for _, lang := range langs {
+		for _, ext := range lang.Extensions {
+			owners[ext] = append(owners[ext], lang.ID())
+		}
+	}
+	result := make(map[string][]string)
+	for ext, candidates := range owners {
+		if len(candidates) < 2 {
+			continue
+		}
+		sort.Strings(candidates)
+		result[ext] = candidates
+	}
+	return result
+}
+
+const ambiguousTemplateSrc = `// Code generated by dev/extract-ctags -ambiguous-out. DO NOT EDIT.
+
+export interface Heuristic {
+    language: string
+    pattern: RegExp
+    weight: number
+}
+
+export interface AmbiguousExtension {
+    candidates: string[]
+    heuristics: Heuristic[]
+}
+
+export const ambiguousExtensions: Record<string, AmbiguousExtension> = {
+{{- range .}}
+    {{quoted .Ext}}: {
+        candidates: [{{range $i, $c := .Candidates}}{{if $i}}, {{end}}{{quoted $c}}{{end}}],
+        heuristics: [{{range .Heuristics}}
+            { language: {{quoted .Language}}, pattern: {{pattern .Pattern}}, weight: {{.Weight}} },{{end}}
+        ],
+    },
+{{- end}}
+}
+`
+
+type ambiguousExtEntry struct {
+	Ext        string
+	Candidates []string
+	Heuristics []heuristic
+}
+
+var ambiguousTemplate = template.Must(template.New("ambiguous").Funcs(template.FuncMap{
+	"quoted": func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "\\'") + "'"
+	},
+	// pattern renders a curated regex source as a JS regex literal. Patterns
+	// are trusted input from heuristics.yaml, not user input.
+	"pattern": func(s string) string {
+		return "/" + s + "/"
+	},
+}).Parse(ambiguousTemplateSrc))
+
+// runAmbiguous computes the extensions claimed by more than one language and
+// writes the ambiguousExtensions.ts artifact to *ambiguousOut.
+func runAmbiguous(langs []langInfo) error {
+	heuristics, err := loadHeuristics(*heuristicsPath)
+	if err != nil {
+		return fmt.Errorf("loading heuristics: %w", err)
+	}
+
+	f, err := os.Create(*ambiguousOut)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return writeAmbiguousExtensions(f, ambiguousExtensions(langs), heuristics)
+}
+
+// writeAmbiguousExtensions renders the ambiguousExtensions.ts artifact for the
+// given ambiguous extension set, enriched with any curated heuristics.
+func writeAmbiguousExtensions(w io.Writer, ambiguous map[string][]string, heuristics heuristicsFile) error {
+	var exts []string
+	for ext := range ambiguous {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	entries := make([]ambiguousExtEntry, 0, len(exts))
+	for _, ext := range exts {
+		// Order each extension's heuristics by language, as its candidates are.
+		hs := append([]heuristic(nil), heuristics[ext]...)
+		sort.SliceStable(hs, func(i, j int) bool { return hs[i].Language < hs[j].Language })
+		entries = append(entries, ambiguousExtEntry{
+			Ext:        ext,
+			Candidates: ambiguous[ext],
+			Heuristics: hs,
+		})
+	}
+	return ambiguousTemplate.Execute(w, entries)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// defaultCommentStyle is the style constant used when nothing else applies.
+// Most generated languages historically defaulted to this even when wrong
+// (see languages.ts commentStyle before this table existed), which silently
+// broke hover/docstring extraction for non-C-family languages.
+const defaultCommentStyle = "cStyle"
+
+// commentStyleTable maps a ctags language name (as reported by
+// --list-map-extensions, e.g. "Python") to the TS comment style constant
+// that language should use. Names are matched case-insensitively.
+var commentStyleTable = map[string]string{
+	"c":            "cStyle",
+	"c++":          "cStyle",
+	"c#":           "cStyle",
+	"java":         "cStyle",
+	"javascript":   "cStyle",
+	"typescript":   "cStyle",
+	"go":           "cStyle",
+	"rust":         "cStyle",
+	"objectivec":   "cStyle",
+	"objective-c":  "cStyle",
+	"swift":        "cStyle",
+	"kotlin":       "cStyle",
+	"scala":        "cStyle",
+	"php":          "cStyle",
+	"sh":           "hashStyle",
+	"python":       "pythonStyle",
+	"ruby":         "hashStyle",
+	"perl":         "hashStyle",
+	"r":            "hashStyle",
+	"make":         "hashStyle",
+	"tcl":          "hashStyle",
+	"yaml":         "hashStyle",
+	"lisp":         "lispStyle",
+	"scheme":       "lispStyle",
+	"clojure":      "lispStyle",
+	"lua":          "sqlStyle", // lua uses "--" line comments, same as sql
+	"sql":          "sqlStyle",
+	"html":         "htmlStyle",
+	"xml":          "htmlStyle",
+	"matlab":       "matlabStyle",
+	"prolog":       "percentStyle",
+	"tex":          "percentStyle",
+	"fortran":      "bangStyle",
+	"forth":        "forthStyle",
+	"apex":         "cStyle",
+	"vb":           "vbStyle",
+}
+
+// commentStylesFile is the shape of the hand-curated -comment-styles YAML
+// override file, keyed by ctags language name.
+type commentStylesFile map[string]string
+
+func loadCommentStyles(path string) (commentStylesFile, error) {
+	if path == "" {
+		return commentStylesFile{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f commentStylesFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// guessCommentStyle is a last resort before falling back to
+// defaultCommentStyle, for languages that are in neither the curated table
+// nor a -comment-styles override. It asks ctags to describe the language's
+// kinds and looks for a handful of telltale words that correlate with a
+// particular comment convention; this catches very little on its own, which
+// is exactly why commentStyleTable is curated by hand instead of relying on
+// it.
+func guessCommentStyle(name string) (string, bool) {
+	out, err := runCtags("--_list-kinds-full=" + name)
+	if err != nil {
+		return "", false
+	}
+	lower := strings.ToLower(out)
+	switch {
+	case strings.Contains(lower, "shebang") || strings.Contains(lower, "hashbang"):
+		return "hashStyle", true
+	default:
+		return "", false
+	}
+}
+
+// commentStyleFor resolves the TS comment style constant for lang, checking
+// the -comment-styles override first, then the curated table, then the
+// ctags-based guess, and finally defaultCommentStyle. It warns to stderr
+// whenever it falls all the way through to the default, so a human knows
+// what to go review (and, ideally, add to commentStyleTable or the override
+// file).
+func commentStyleFor(lang langInfo, overrides commentStylesFile) string {
+	if style, ok := overrides[lang.Name]; ok {
+		return style
+	}
+	if style, ok := commentStyleTable[strings.ToLower(lang.Name)]; ok {
+		return style
+	}
+	if style, ok := guessCommentStyle(lang.Name); ok {
+		return style
+	}
+	log.Printf("Warning: no known comment style for %q, defaulting to %s; please review", lang.Name, defaultCommentStyle)
+	return defaultCommentStyle
+}
+
+// assignCommentStyles fills in lang.CommentStyle for every lang, using
+// overrides loaded from *commentStylesPath.
+func assignCommentStyles(langs []langInfo) ([]langInfo, error) {
+	overrides, err := loadCommentStyles(*commentStylesPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading comment styles: %w", err)
+	}
+	result := make([]langInfo, len(langs))
+	for i, lang := range langs {
+		lang.CommentStyle = commentStyleFor(lang, overrides)
+		result[i] = lang
+	}
+	return result, nil
+}
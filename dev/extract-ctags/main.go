@@ -7,6 +7,11 @@
 // Output is written to stdout and consists of a block of handler arguments in
 // the style expected by languages.ts. The output should be correctly formatted
 // but must be vetted by a human before checking it in.
+//
+// Run with -merge -existing shared/language-specs/languages.ts to instead
+// merge ctags' current view directly into that file, in the style of the
+// extract -> merge cycle used by x/text/message/pipeline. See -help for the
+// full set of flags this mode accepts.
 package main
 
 import (
@@ -24,14 +29,26 @@ import (
 )
 
 // This block should express a LanguageSpec, see the languageSpecs const in
-// languages.ts for the expected format of the output.
+// languages.ts for the expected format of the output. filenames, aliases, and
+// interpreters are optional fields on LanguageSpec, so - matching every
+// hand-curated entry - they're only emitted when there's something to put in
+// them, rather than as empty-array boilerplate.
 const templateSrc = `{
     languageID: {{quoted .ID}},
     stylized: {{quoted .Name}},
     fileExts: [{{range .Extensions}}
         {{quoted .}},{{end}}
-    ],
-    commentStyle: cStyle,
+    ],{{if .Filenames}}
+    filenames: [{{range .Filenames}}
+        {{quoted .}},{{end}}
+    ],{{end}}{{if .Aliases}}
+    aliases: [{{range .Aliases}}
+        {{quoted .}},{{end}}
+    ],{{end}}{{if .Interpreters}}
+    interpreters: [{{range .Interpreters}}
+        {{quoted .}},{{end}}
+    ],{{end}}
+    commentStyle: {{.CommentStyle}},
 },
 `
 
@@ -40,6 +57,17 @@ var (
 	langFile  = flag.String("existing", "", "Path of existing languages file (.ts)")
 	doAll     = flag.Bool("all", false, "Generate all available languages, modulo filter")
 
+	ambiguousOut   = flag.String("ambiguous-out", "", "Write ambiguousExtensions.ts (covering extensions shared by more than one language) to this path instead of emitting language skeletons")
+	heuristicsPath = flag.String("heuristics", "", "Path of hand-curated YAML file of disambiguation heuristics, used with -ambiguous-out")
+
+	doMerge = flag.Bool("merge", false, "Merge ctags output into -existing in place, instead of printing a skeleton to paste by hand")
+	dryRun  = flag.Bool("dry-run", false, "With -merge, print a unified diff instead of writing -existing")
+	backup  = flag.Bool("backup", false, "With -merge, write the pre-merge contents of -existing to <file>.bak")
+
+	interpretersPath = flag.String("interpreters", "", "Path of hand-curated YAML file mapping language name to shebang interpreter names (e.g. python3), since ctags has no notion of these")
+
+	commentStylesPath = flag.String("comment-styles", "", "Path of hand-curated YAML file overriding the comment style constant used for a language name, taking priority over the built-in table")
+
 	filterBy = regexp.MustCompile(`stylized: +'(.*?)',?`)
 
 	output = template.Must(template.New("lang").Funcs(template.FuncMap{
@@ -63,6 +91,31 @@ If -existing is specified, the tool scans that file for languages that appear
 already to be implemented, and filters them from the output. This is useful
 in conjunction with -all.
 
+If -ambiguous-out is specified, the tool instead writes ambiguousExtensions.ts
+to that path: a table of every extension claimed by more than one language,
+together with any disambiguation heuristics supplied via -heuristics, for use
+by the detectLanguage helper on the TypeScript side.
+
+If -merge is specified (requires -existing), the tool parses the existing
+languageSpecs array and merges ctags' current view of the world into it in
+place: new languages are inserted in sorted position, existing languages have
+any newly-reported extensions appended, and languages no longer reported by
+ctags are left alone but noted. Combine with -dry-run to print a unified diff
+instead of writing the file, and -backup to additionally save the pre-merge
+contents as <file>.bak.
+
+Every mode also collects, per language, the well-known filenames and aliases
+universal-ctags reports (via --list-map-patterns and --list-aliases), plus
+shebang interpreters from the -interpreters YAML file, since ctags has no
+notion of those.
+
+The generated commentStyle is looked up from a curated table of ctags
+language name -> style constant (falling back to a guess from the language's
+ctags kind descriptions, then cStyle), so that emitted languages don't all silently claim C-style comments.
+Use -comment-styles=FILE to override or extend the table without touching
+the Go source; a warning is printed to stderr for any language that still
+falls through to the cStyle default, so a human knows what to review.
+
 Options:
 `, filepath.Base(os.Args[0]))
 		flag.PrintDefaults()
@@ -71,14 +124,41 @@ Options:
 
 func main() {
 	flag.Parse()
-	if flag.NArg() == 0 && !*doAll {
-		log.Fatal("You must specify languages to generate, or -all")
-	}
 
 	langs, err := listMapExtensions()
 	if err != nil {
 		log.Fatalf("Parsing language extensions: %v", err)
 	}
+	langs, err = enrichLangs(langs)
+	if err != nil {
+		log.Fatalf("Collecting filenames, aliases, and interpreters: %v", err)
+	}
+	langs, err = assignCommentStyles(langs)
+	if err != nil {
+		log.Fatalf("Assigning comment styles: %v", err)
+	}
+
+	if *ambiguousOut != "" {
+		if err := runAmbiguous(langs); err != nil {
+			log.Fatalf("Generating ambiguous extensions: %v", err)
+		}
+		return
+	}
+
+	if *doMerge {
+		if *langFile == "" {
+			log.Fatal("-merge requires -existing")
+		}
+		if err := runMerge(langs); err != nil {
+			log.Fatalf("Merging into %s: %v", *langFile, err)
+		}
+		return
+	}
+
+	if flag.NArg() == 0 && !*doAll {
+		log.Fatal("You must specify languages to generate, or -all")
+	}
+
 	exists, err := knownLanguages(*langFile)
 	if err != nil {
 		log.Fatalf("Reading known languages: %v", err)
@@ -106,8 +186,12 @@ func wantLang(name string) bool {
 }
 
 type langInfo struct {
-	Name       string
-	Extensions []string // ordered lexicographically
+	Name         string
+	Extensions   []string // ordered lexicographically
+	Filenames    []string // ordered lexicographically; well-known names with no useful extension
+	Aliases      []string // ordered lexicographically; alternate names ctags also accepts
+	Interpreters []string // ordered lexicographically; shebang interpreters, from -interpreters
+	CommentStyle string   // name of the TS comment style constant, e.g. "cStyle"
 }
 
 // Return the expected language ID for the specified language.
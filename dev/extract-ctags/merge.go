@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// This mirrors the extract -> merge cycle used by x/text/message/pipeline:
+// extract-ctags already "extracts" a skeleton for new languages; -merge
+// additionally "merges" that extraction back into the existing languages.ts
+// in place, rather than leaving a human to hand-paste it.
+
+const arrayHeader = "languageSpecs: LanguageSpec[] = ["
+
+var (
+	languageIDRe = regexp.MustCompile(`languageID:\s*'([^']*)'`)
+	// The second group greedily soaks up any whitespace immediately before
+	// the closing ']', so group 1 (lazy) captures only the actual extension
+	// entries and augmentFileExts can insert right after them without
+	// leaving a blank line or gluing the new entry onto the closing bracket.
+	// A single-line array (e.g. ['py']) has no such whitespace, so there the
+	// new entries end directly before the ']'.
+	fileExtsRe = regexp.MustCompile(`(?s)fileExts:\s*\[(.*?)(\s*)\]`)
+	quotedRe   = regexp.MustCompile(`'([^']*)'`)
+)
+
+// specEntry is one top-level object literal from the languageSpecs array,
+// together with the raw separator text (comma, whitespace, comments) that
+// followed it in the original source. Entries that are left untouched are
+// written back out byte-for-byte.
+type specEntry struct {
+	languageID string
+	fileExts   []string
+	text       string
+	gap        string
+}
+
+// mergeReport summarizes what a -merge run did, for the human to skim.
+type mergeReport struct {
+	Added   []string // languageIDs inserted as new skeleton entries
+	Updated []string // languageIDs whose fileExts gained new extensions
+	Removed []string // languageIDs present in the file but no longer in ctags
+}
+
+func runMerge(langs []langInfo) error {
+	src, err := os.ReadFile(*langFile)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", *langFile, err)
+	}
+
+	merged, report, err := mergeLanguageSpecs(string(src), langs)
+	if err != nil {
+		return err
+	}
+
+	reportMerge(report)
+
+	if string(src) == merged {
+		log.Printf("No changes to %s", *langFile)
+		return nil
+	}
+
+	if *dryRun {
+		return printDiff(os.Stdout, *langFile, src, []byte(merged))
+	}
+
+	if *backup {
+		if err := os.WriteFile(*langFile+".bak", src, 0o644); err != nil {
+			return fmt.Errorf("writing backup: %w", err)
+		}
+	}
+	return os.WriteFile(*langFile, []byte(merged), 0o644)
+}
+
+func reportMerge(r mergeReport) {
+	for _, id := range r.Added {
+		log.Printf("Added language: %s", id)
+	}
+	for _, id := range r.Updated {
+		log.Printf("Updated extensions for language: %s", id)
+	}
+	for _, id := range r.Removed {
+		log.Printf("Warning: languages.ts has %q but ctags no longer reports it; left untouched", id)
+	}
+}
+
+// mergeLanguageSpecs parses the languageSpecs array out of src and returns an
+// updated source with new languages inserted in sorted position and existing
+// entries' fileExts augmented with any extensions ctags now knows about.
+// Entries no longer reported by ctags, and any hand-customized fields on
+// existing entries, are preserved verbatim.
+func mergeLanguageSpecs(src string, langs []langInfo) (string, mergeReport, error) {
+	headerIdx := strings.Index(src, arrayHeader)
+	if headerIdx == -1 {
+		return "", mergeReport{}, fmt.Errorf("could not find %q in %s", arrayHeader, *langFile)
+	}
+	openIdx := headerIdx + len(arrayHeader) // index just past '['
+	closeIdx, err := matchingBracket(src, openIdx)
+	if err != nil {
+		return "", mergeReport{}, err
+	}
+
+	leadingGap, entries, err := splitEntries(src[openIdx:closeIdx])
+	if err != nil {
+		return "", mergeReport{}, err
+	}
+
+	byID := make(map[string]int, len(entries))
+	for i, e := range entries {
+		byID[e.languageID] = i
+	}
+
+	var report mergeReport
+	ctagsIDs := make(map[string]bool, len(langs))
+	for _, lang := range langs {
+		ctagsIDs[lang.ID()] = true
+
+		if i, ok := byID[lang.ID()]; ok {
+			updated, changed := augmentFileExts(entries[i], lang.Extensions)
+			if changed {
+				entries[i] = updated
+				report.Updated = append(report.Updated, lang.ID())
+			}
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := output.Execute(&buf, lang); err != nil {
+			return "", mergeReport{}, fmt.Errorf("generating skeleton for %q: %w", lang.Name, err)
+		}
+		entries = insertSorted(entries, specEntry{
+			languageID: lang.ID(),
+			fileExts:   lang.Extensions,
+			text:       strings.TrimRight(buf.String(), "\n,") + ",",
+			gap:        "\n    ",
+		})
+		// insertSorted shifts every later entry along by one.
+		for j, e := range entries {
+			byID[e.languageID] = j
+		}
+		report.Added = append(report.Added, lang.ID())
+	}
+
+	for _, e := range entries {
+		if !ctagsIDs[e.languageID] {
+			report.Removed = append(report.Removed, e.languageID)
+		}
+	}
+
+	var body strings.Builder
+	body.WriteString(leadingGap)
+	for _, e := range entries {
+		body.WriteString(e.text)
+		body.WriteString(e.gap)
+	}
+
+	return src[:openIdx] + body.String() + src[closeIdx:], report, nil
+}
+
+// matchingBracket returns the index of the ']' matching the '[' immediately
+// preceding openIdx, tracking nested '[' and '{' pairs in between.
+func matchingBracket(src string, openIdx int) (int, error) {
+	depth := 1
+	for i := openIdx; i < len(src); i++ {
+		switch src[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced brackets while scanning languageSpecs array")
+}
+
+// splitEntries splits the interior of the languageSpecs array into its
+// top-level object entries, preserving exact formatting of everything
+// between and around them.
+func splitEntries(body string) (leadingGap string, entries []specEntry, err error) {
+	depth := 0
+	entryStart := -1
+	gapStart := 0
+	sawFirstEntry := false
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '{', '[':
+			if depth == 0 && body[i] == '{' && entryStart == -1 {
+				if !sawFirstEntry {
+					leadingGap = body[gapStart:i]
+					sawFirstEntry = true
+				}
+				entryStart = i
+			}
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 && entryStart != -1 {
+				text := body[entryStart : i+1]
+				gapStart = i + 1
+				// Absorb a trailing comma into the entry text, matching the
+				// style already used by the generator template.
+				if gapStart < len(body) && body[gapStart] == ',' {
+					text += ","
+					gapStart++
+				}
+				entry, err := parseEntry(text)
+				if err != nil {
+					return "", nil, err
+				}
+				entries = append(entries, entry) // gap filled in below
+				entryStart = -1
+
+				// Find where the next entry (if any) begins, so this one's
+				// gap captures any whitespace/comments between them.
+				next := gapStart
+				for next < len(body) && body[next] != '{' {
+					next++
+				}
+				entries[len(entries)-1].gap = body[gapStart:next]
+				gapStart = next
+			}
+		}
+	}
+	if depth != 0 {
+		return "", nil, fmt.Errorf("unbalanced brackets inside languageSpecs array")
+	}
+	return leadingGap, entries, nil
+}
+
+func parseEntry(text string) (specEntry, error) {
+	m := languageIDRe.FindStringSubmatch(text)
+	if m == nil {
+		return specEntry{}, fmt.Errorf("entry has no languageID: %s", text)
+	}
+	entry := specEntry{languageID: m[1], text: text}
+	if fm := fileExtsRe.FindStringSubmatch(text); fm != nil {
+		for _, qm := range quotedRe.FindAllStringSubmatch(fm[1], -1) {
+			entry.fileExts = append(entry.fileExts, qm[1])
+		}
+	}
+	return entry, nil
+}
+
+// augmentFileExts appends any of newExts not already present in the entry's
+// fileExts array, preserving the existing extensions' order and the
+// indentation style of the existing entries. It never removes an extension.
+func augmentFileExts(e specEntry, newExts []string) (specEntry, bool) {
+	known := make(map[string]bool, len(e.fileExts))
+	for _, ext := range e.fileExts {
+		known[ext] = true
+	}
+	var toAdd []string
+	for _, ext := range newExts {
+		if !known[ext] {
+			toAdd = append(toAdd, ext)
+		}
+	}
+	if len(toAdd) == 0 {
+		return e, false
+	}
+
+	loc := fileExtsRe.FindStringSubmatchIndex(e.text)
+	if loc == nil {
+		return e, false
+	}
+	// loc[2]:loc[3] is group 1 (the extension entries, excluding any
+	// whitespace trailing right before ']'), so inserting at loc[3] lands
+	// right after the last real entry and leaves that trailing whitespace -
+	// and the ']' itself - untouched.
+	existing := e.text[loc[2]:loc[3]]
+
+	indent := "        "
+	if m := regexp.MustCompile(`\n(\s*)'`).FindStringSubmatch(existing); m != nil {
+		indent = m[1]
+	}
+
+	var add strings.Builder
+	// A single-line fileExts array (e.g. ['c', 'h']) has no trailing comma
+	// after its last entry; a comma must be added before appending more.
+	if trimmed := strings.TrimRight(existing, " \t\r\n"); trimmed != "" && !strings.HasSuffix(trimmed, ",") {
+		add.WriteString(",")
+	}
+	for _, ext := range toAdd {
+		add.WriteString("\n")
+		add.WriteString(indent)
+		add.WriteString("'")
+		add.WriteString(strings.ReplaceAll(ext, "'", "\\'"))
+		add.WriteString("',")
+	}
+
+	e.text = e.text[:loc[3]] + add.String() + e.text[loc[3]:]
+	e.fileExts = append(append([]string{}, e.fileExts...), toAdd...)
+	return e, true
+}
+
+// insertSorted inserts a new entry in languageID order among entries that
+// are themselves already sorted, matching the order -all already produces.
+func insertSorted(entries []specEntry, n specEntry) []specEntry {
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].languageID >= n.languageID })
+	entries = append(entries, specEntry{})
+	copy(entries[i+1:], entries[i:])
+	entries[i] = n
+	return entries
+}
+
+// printDiff writes a unified diff of old -> new to w, in the style a human
+// would get from `git diff`, by shelling out to the system diff tool.
+func printDiff(w io.Writer, path string, old, updated []byte) error {
+	oldFile, err := os.CreateTemp("", "languages-old-*.ts")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(oldFile.Name())
+	newFile, err := os.CreateTemp("", "languages-new-*.ts")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(newFile.Name())
+
+	if _, err := oldFile.Write(old); err != nil {
+		return err
+	}
+	if _, err := newFile.Write(updated); err != nil {
+		return err
+	}
+
+	out, err := exec.Command("diff", "-u", "--label", path, "--label", path, oldFile.Name(), newFile.Name()).Output()
+	// diff exits 1 when inputs differ, which is the expected case here.
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return err
+		}
+	}
+	_, err = w.Write(out)
+	return err
+}
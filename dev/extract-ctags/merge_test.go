@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const fixtureLanguageSpecs = `// some header comment
+export const languageSpecs: LanguageSpec[] = [
+    {
+        languageID: 'c',
+        stylized: 'C',
+        fileExts: [
+            'c',
+            'h',
+        ],
+        commentStyle: cStyle,
+    },
+    {
+        languageID: 'python',
+        stylized: 'Python',
+        fileExts: ['py'],
+        commentStyle: pythonStyle,
+    },
+]
+`
+
+func TestMergeLanguageSpecsNoOp(t *testing.T) {
+	langs := []langInfo{
+		{Name: "C", Extensions: []string{"c", "h"}, CommentStyle: "cStyle"},
+		{Name: "Python", Extensions: []string{"py"}, CommentStyle: "pythonStyle"},
+	}
+
+	merged, report, err := mergeLanguageSpecs(fixtureLanguageSpecs, langs)
+	if err != nil {
+		t.Fatalf("mergeLanguageSpecs: %v", err)
+	}
+	if merged != fixtureLanguageSpecs {
+		t.Errorf("expected a true no-op to leave the source byte-for-byte unchanged, got:\n%s", merged)
+	}
+	if len(report.Added) != 0 || len(report.Updated) != 0 || len(report.Removed) != 0 {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+}
+
+func TestMergeLanguageSpecsAugmentsMultiLineFileExts(t *testing.T) {
+	langs := []langInfo{
+		{Name: "C", Extensions: []string{"c", "h", "inc"}, CommentStyle: "cStyle"},
+		{Name: "Python", Extensions: []string{"py"}, CommentStyle: "pythonStyle"},
+	}
+
+	merged, report, err := mergeLanguageSpecs(fixtureLanguageSpecs, langs)
+	if err != nil {
+		t.Fatalf("mergeLanguageSpecs: %v", err)
+	}
+	if got := report.Updated; len(got) != 1 || got[0] != "c" {
+		t.Fatalf("expected report.Updated = [c], got %v", got)
+	}
+
+	const wantCBlock = `fileExts: [
+            'c',
+            'h',
+            'inc',
+        ],`
+	if !strings.Contains(merged, wantCBlock) {
+		t.Errorf("expected merged output to contain:\n%s\ngot:\n%s", wantCBlock, merged)
+	}
+	// The insertion must not leave a dangling blank line or glue the new
+	// entry onto the closing bracket.
+	if strings.Contains(merged, ",\n            \n") {
+		t.Errorf("merged output has a dangling blank line:\n%s", merged)
+	}
+	if strings.Contains(merged, "',]") {
+		t.Errorf("merged output glued a new extension onto the closing bracket:\n%s", merged)
+	}
+}
+
+func TestMergeLanguageSpecsAugmentsSingleLineFileExts(t *testing.T) {
+	langs := []langInfo{
+		{Name: "C", Extensions: []string{"c", "h"}, CommentStyle: "cStyle"},
+		{Name: "Python", Extensions: []string{"py", "pyw"}, CommentStyle: "pythonStyle"},
+	}
+
+	merged, _, err := mergeLanguageSpecs(fixtureLanguageSpecs, langs)
+	if err != nil {
+		t.Fatalf("mergeLanguageSpecs: %v", err)
+	}
+
+	const wantPythonBlock = `fileExts: ['py',
+        'pyw',],`
+	if !strings.Contains(merged, wantPythonBlock) {
+		t.Errorf("expected merged output to contain:\n%s\ngot:\n%s", wantPythonBlock, merged)
+	}
+}
+
+func TestMergeLanguageSpecsInsertsNewLanguageInSortedPosition(t *testing.T) {
+	langs := []langInfo{
+		{Name: "C", Extensions: []string{"c", "h"}, CommentStyle: "cStyle"},
+		{Name: "Go", Extensions: []string{"go"}, CommentStyle: "cStyle"},
+		{Name: "Python", Extensions: []string{"py"}, CommentStyle: "pythonStyle"},
+	}
+
+	merged, report, err := mergeLanguageSpecs(fixtureLanguageSpecs, langs)
+	if err != nil {
+		t.Fatalf("mergeLanguageSpecs: %v", err)
+	}
+	if got := report.Added; len(got) != 1 || got[0] != "go" {
+		t.Fatalf("expected report.Added = [go], got %v", got)
+	}
+
+	cIdx := strings.Index(merged, "languageID: 'c'")
+	goIdx := strings.Index(merged, "languageID: 'go'")
+	pyIdx := strings.Index(merged, "languageID: 'python'")
+	if !(cIdx < goIdx && goIdx < pyIdx) {
+		t.Errorf("expected go to be inserted alphabetically between c and python, got order in:\n%s", merged)
+	}
+}
+
+func TestMergeLanguageSpecsReportsRemovedWithoutDeleting(t *testing.T) {
+	langs := []langInfo{
+		{Name: "C", Extensions: []string{"c", "h"}, CommentStyle: "cStyle"},
+	}
+
+	merged, report, err := mergeLanguageSpecs(fixtureLanguageSpecs, langs)
+	if err != nil {
+		t.Fatalf("mergeLanguageSpecs: %v", err)
+	}
+	if got := report.Removed; len(got) != 1 || got[0] != "python" {
+		t.Fatalf("expected report.Removed = [python], got %v", got)
+	}
+	if !strings.Contains(merged, "languageID: 'python'") {
+		t.Errorf("expected the python entry to be left in place, got:\n%s", merged)
+	}
+}
+
+func TestPrintDiff(t *testing.T) {
+	if _, err := exec.LookPath("diff"); err != nil {
+		t.Skip("diff(1) not available")
+	}
+
+	old := []byte("a\nb\nc\n")
+	updated := []byte("a\nx\nc\n")
+
+	var buf bytes.Buffer
+	if err := printDiff(&buf, "languages.ts", old, updated); err != nil {
+		t.Fatalf("printDiff: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "-b") || !strings.Contains(out, "+x") {
+		t.Errorf("expected diff output to show b -> x, got:\n%s", out)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// enrichLangs augments each langInfo with Filenames, Aliases, and
+// Interpreters, pulled from universal-ctags where it knows them and from the
+// hand-curated -interpreters file where it doesn't.
+func enrichLangs(langs []langInfo) ([]langInfo, error) {
+	filenames, err := listMapPatterns()
+	if err != nil {
+		return nil, fmt.Errorf("listing map patterns: %w", err)
+	}
+	aliases, err := listAliases()
+	if err != nil {
+		return nil, fmt.Errorf("listing aliases: %w", err)
+	}
+	interpreters, err := loadInterpreters(*interpretersPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading interpreters: %w", err)
+	}
+
+	result := make([]langInfo, len(langs))
+	for i, lang := range langs {
+		lang.Filenames = filenames[lang.Name]
+		lang.Aliases = aliases[lang.Name]
+		lang.Interpreters = interpreters[strings.ToLower(lang.Name)]
+		result[i] = lang
+	}
+	return result, nil
+}
+
+// listMapPatterns returns, for each language, the well-known filenames
+// universal-ctags recognizes without relying on a file extension (e.g.
+// "Makefile", "Dockerfile", "Rakefile"). Glob-style patterns (anything
+// containing "*") are not filenames and are skipped: they add no signal
+// beyond what listMapExtensions already derives from --list-map-extensions.
+func listMapPatterns() (map[string][]string, error) {
+	out, err := runCtags("--list-map-patterns")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]bool)
+	for i, line := range splitNonEmptyLines(out) {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			log.Printf("Warning: list-map-patterns line %d is malformed (%q)", i+1, line)
+			continue
+		}
+		lang, pattern := fields[0], fields[1]
+		if strings.Contains(pattern, "*") {
+			continue // a glob, not a bare filename
+		}
+		if result[lang] == nil {
+			result[lang] = map[string]bool{}
+		}
+		result[lang][pattern] = true
+	}
+	return sortedSetMap(result), nil
+}
+
+// listAliases returns every alternate name universal-ctags accepts for a
+// language (e.g. "c++" recognizing the alias "cpp").
+func listAliases() (map[string][]string, error) {
+	out, err := runCtags("--list-aliases")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]bool)
+	for i, line := range splitNonEmptyLines(out) {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			log.Printf("Warning: list-aliases line %d is malformed (%q)", i+1, line)
+			continue
+		}
+		lang, alias := fields[0], fields[1]
+		if result[lang] == nil {
+			result[lang] = map[string]bool{}
+		}
+		result[lang][alias] = true
+	}
+	return sortedSetMap(result), nil
+}
+
+// interpretersFile is the shape of the hand-curated -interpreters YAML file,
+// keyed by language name, since universal-ctags has no notion of shebang
+// interpreters: it only maps filenames and extensions.
+type interpretersFile map[string][]string
+
+func loadInterpreters(path string) (interpretersFile, error) {
+	if path == "" {
+		return interpretersFile{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f interpretersFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for lang, interpreters := range f {
+		sort.Strings(interpreters)
+		f[lang] = interpreters
+	}
+	return f, nil
+}
+
+func runCtags(args ...string) (string, error) {
+	tool := *ctagsPath
+	if tool == "" {
+		tool = "universal-ctags"
+	}
+	out, err := exec.Command(tool, args...).Output()
+	if err != nil {
+		if e, ok := err.(*exec.ExitError); ok {
+			err = errors.New(string(e.Stderr))
+		}
+		return "", fmt.Errorf("running universal-ctags %s: %v", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+func splitNonEmptyLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func sortedSetMap(m map[string]map[string]bool) map[string][]string {
+	result := make(map[string][]string, len(m))
+	for lang, set := range m {
+		var values []string
+		for v := range set {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		result[lang] = values
+	}
+	return result
+}